@@ -0,0 +1,95 @@
+package waitgrouppool
+
+import (
+    "context"
+    "math"
+    "sync"
+)
+
+// ErrWaitGroupPool is a WaitGroupPool analogous to golang.org/x/sync/errgroup.Group:
+// it runs functions that return an error, cancels the derived context as soon as
+// one of them fails, and Wait returns the first error encountered.
+type ErrWaitGroupPool struct {
+    wgp *WaitGroupPool
+    ctx context.Context
+
+    cancel context.CancelFunc
+
+    errOnce sync.Once
+    err     error
+}
+
+// WithContext returns a new ErrWaitGroupPool and a context derived from ctx.
+// The derived context is canceled the first time a function passed to Go or
+// TryGo returns a non-nil error, or the first time Wait returns, whichever
+// occurs first. The pool has no concurrency limit until SetLimit is called.
+func WithContext(ctx context.Context) (*ErrWaitGroupPool, context.Context) {
+    ctx, cancel := context.WithCancel(ctx)
+
+    g := &ErrWaitGroupPool{
+        wgp:    New(0),
+        cancel: cancel,
+    }
+    g.ctx = ctx
+
+    return g, ctx
+}
+
+// SetLimit resizes the underlying semaphore to n. It must be called before
+// any call to Go or TryGo, or once every function passed to them has
+// returned (e.g. after Wait): a function still running when SetLimit is
+// called releases its slot into the new semaphore, which blocks it if that
+// semaphore holds no slot. A negative n removes the limit.
+func (g *ErrWaitGroupPool) SetLimit(n int) {
+    size := n
+    if n < 0 {
+        size = math.MaxInt32
+    }
+
+    g.wgp.Size = size
+    g.wgp.sem = NewSemaphore(size)
+}
+
+// Go acquires a slot in the pool, blocking (and honoring the context passed to
+// WithContext) until one is free, then calls f in a new goroutine. If f
+// returns a non-nil error, the derived context is canceled and the error is
+// recorded for Wait, unless another call has already recorded one.
+func (g *ErrWaitGroupPool) Go(f func() error) {
+    if _, err := g.wgp.AddWithContext(g.ctx, 1); err != nil {
+        return
+    }
+
+    go g.runAndRelease(f)
+}
+
+// TryGo acquires a slot and calls f in a new goroutine, same as Go, but
+// returns false immediately instead of blocking if the pool is at capacity.
+func (g *ErrWaitGroupPool) TryGo(f func() error) bool {
+    if !g.wgp.tryAcquire() {
+        return false
+    }
+
+    go g.runAndRelease(f)
+
+    return true
+}
+
+func (g *ErrWaitGroupPool) runAndRelease(f func() error) {
+    defer g.wgp.Done()
+
+    if err := f(); err != nil {
+        g.errOnce.Do(func() {
+            g.err = err
+            g.cancel()
+        })
+    }
+}
+
+// Wait blocks until all function calls from Go and TryGo have returned, then
+// cancels the derived context and returns the first non-nil error, if any.
+func (g *ErrWaitGroupPool) Wait() error {
+    g.wgp.Wait()
+    g.cancel()
+
+    return g.err
+}
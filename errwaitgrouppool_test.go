@@ -0,0 +1,101 @@
+package waitgrouppool
+
+import (
+    "context"
+    "errors"
+    "sync/atomic"
+    "testing"
+)
+
+func TestErrWaitGroupPoolGo(t *testing.T) {
+    g, ctx := WithContext(context.Background())
+    var c uint32
+
+    for i := 0; i < 1000; i++ {
+        g.Go(func() error {
+            atomic.AddUint32(&c, 1)
+            return nil
+        })
+    }
+
+    if err := g.Wait(); err != nil {
+        t.Fatalf("Wait returned error: %v", err)
+    }
+
+    if c != 1000 {
+        t.Fatalf("%d, not all routines have been executed.", c)
+    }
+
+    if ctx.Err() == nil {
+        t.Fatalf("the derived context should be canceled once Wait returns.")
+    }
+}
+
+func TestErrWaitGroupPoolFirstErrorCancels(t *testing.T) {
+    g, ctx := WithContext(context.Background())
+    boom := errors.New("boom")
+
+    g.Go(func() error {
+        return boom
+    })
+    g.Go(func() error {
+        <-ctx.Done()
+        return ctx.Err()
+    })
+
+    if err := g.Wait(); err != boom {
+        t.Fatalf("Wait returned %v, want the first recorded error", err)
+    }
+}
+
+func TestErrWaitGroupPoolTryGoAtCapacity(t *testing.T) {
+    g, _ := WithContext(context.Background())
+    g.SetLimit(1)
+
+    release := make(chan struct{})
+    started := make(chan struct{})
+
+    g.Go(func() error {
+        close(started)
+        <-release
+        return nil
+    })
+    <-started
+
+    if g.TryGo(func() error { return nil }) {
+        t.Fatalf("TryGo should fail when the pool is at capacity.")
+    }
+
+    close(release)
+
+    if err := g.Wait(); err != nil {
+        t.Fatalf("Wait returned error: %v", err)
+    }
+}
+
+func TestErrWaitGroupPoolSetLimit(t *testing.T) {
+    g, _ := WithContext(context.Background())
+    g.SetLimit(2)
+
+    release := make(chan struct{})
+    hold := func() error {
+        <-release
+        return nil
+    }
+
+    if !g.TryGo(hold) {
+        t.Fatalf("TryGo should succeed within the configured limit.")
+    }
+    if !g.TryGo(hold) {
+        t.Fatalf("TryGo should succeed within the configured limit.")
+    }
+    if g.TryGo(func() error { return nil }) {
+        t.Fatalf("TryGo should fail once the configured limit is reached.")
+    }
+
+    close(release)
+
+    if err := g.Wait(); err != nil {
+        t.Fatalf("Wait returned error: %v", err)
+    }
+}
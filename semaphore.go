@@ -0,0 +1,87 @@
+package waitgrouppool
+
+import (
+    "context"
+    "math"
+)
+
+// Semaphore is a capacity-limited throttle backed by a buffered channel. It
+// implements sync.Locker, with Lock/Unlock acquiring and releasing a single
+// slot, so it can be used as a standalone limiter in places that don't need
+// the rest of WaitGroupPool's WaitGroup semantics, e.g. around code that
+// manages its own goroutine lifecycle.
+type Semaphore struct {
+    slots chan struct{}
+}
+
+// NewSemaphore creates a Semaphore with the given capacity.
+// A capacity <= 0 means unlimited.
+func NewSemaphore(capacity int) *Semaphore {
+    size := capacity
+    if size <= 0 {
+        size = math.MaxInt32 // 2^31 - 1
+    }
+
+    return &Semaphore{slots: make(chan struct{}, size)}
+}
+
+// Lock acquires a slot, blocking until one is available.
+func (s *Semaphore) Lock() {
+    s.slots <- struct{}{}
+}
+
+// Unlock releases a slot.
+// See sync.Locker documentation for more information.
+func (s *Semaphore) Unlock() {
+    <-s.slots
+}
+
+// TryLock acquires a slot without blocking.
+// It returns false if the semaphore is already at capacity.
+func (s *Semaphore) TryLock() bool {
+    select {
+    case s.slots <- struct{}{}:
+        return true
+    default:
+        return false
+    }
+}
+
+// LockWithContext acquires a slot, blocking until one is available or ctx is
+// done. It returns ctx.Err() if ctx is done before a slot is acquired.
+func (s *Semaphore) LockWithContext(ctx context.Context) error {
+    select {
+    case s.slots <- struct{}{}:
+        return nil
+    case <-ctx.Done():
+        return ctx.Err()
+    }
+}
+
+// LockWithContexts acquires a slot, blocking until one is available or
+// either ctx1 or ctx2 is done. It reports which of the two, if any, ended
+// the wait, so callers that derive distinct meaning from each context (e.g.
+// a caller timeout versus a pool shutdown) can tell them apart; the
+// returned context is nil on success. This lets callers race a second
+// cancellation signal against Lock without reaching into the Semaphore's
+// internal channel.
+func (s *Semaphore) LockWithContexts(ctx1, ctx2 context.Context) (context.Context, error) {
+    select {
+    case s.slots <- struct{}{}:
+        return nil, nil
+    case <-ctx1.Done():
+        return ctx1, ctx1.Err()
+    case <-ctx2.Done():
+        return ctx2, ctx2.Err()
+    }
+}
+
+// Len returns the number of slots currently held.
+func (s *Semaphore) Len() int {
+    return len(s.slots)
+}
+
+// Cap returns the total capacity of the semaphore.
+func (s *Semaphore) Cap() int {
+    return cap(s.slots)
+}
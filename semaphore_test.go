@@ -0,0 +1,67 @@
+package waitgrouppool
+
+import (
+    "context"
+    "testing"
+)
+
+func TestSemaphoreTryLock(t *testing.T) {
+    sem := NewSemaphore(1)
+
+    if !sem.TryLock() {
+        t.Fatalf("TryLock should succeed when the semaphore is empty.")
+    }
+
+    if sem.TryLock() {
+        t.Fatalf("TryLock should fail when the semaphore is at capacity.")
+    }
+
+    sem.Unlock()
+
+    if !sem.TryLock() {
+        t.Fatalf("TryLock should succeed again after Unlock.")
+    }
+}
+
+func TestSemaphoreLockWithContext(t *testing.T) {
+    sem := NewSemaphore(1)
+    sem.Lock()
+
+    ctx, cancel := context.WithCancel(context.Background())
+    cancel()
+
+    if err := sem.LockWithContext(ctx); err != context.Canceled {
+        t.Fatalf("LockWithContext returned non-context.Canceled error: %v", err)
+    }
+}
+
+func TestSemaphoreLockWithContexts(t *testing.T) {
+    sem := NewSemaphore(1)
+    sem.Lock()
+
+    ctx1, cancel1 := context.WithCancel(context.Background())
+    defer cancel1()
+    ctx2, cancel2 := context.WithCancel(context.Background())
+    cancel2()
+
+    doneCtx, err := sem.LockWithContexts(ctx1, ctx2)
+    if err != context.Canceled {
+        t.Fatalf("LockWithContexts returned non-context.Canceled error: %v", err)
+    }
+    if doneCtx != ctx2 {
+        t.Fatalf("LockWithContexts did not report ctx2 as the context that ended the wait.")
+    }
+}
+
+func TestSemaphoreLenCap(t *testing.T) {
+    sem := NewSemaphore(2)
+
+    if sem.Cap() != 2 {
+        t.Fatalf("Cap() = %d, want 2", sem.Cap())
+    }
+
+    sem.Lock()
+    if sem.Len() != 1 {
+        t.Fatalf("Len() = %d, want 1", sem.Len())
+    }
+}
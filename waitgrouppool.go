@@ -3,21 +3,40 @@ package waitgrouppool
 import (
     "context"
     "errors"
+    "fmt"
     "math"
     "sync"
+    "sync/atomic"
     "time"
 )
 
 const DEFAULT_ASSIGNMENT_TIMEOUT = time.Second * 60
 
+// ErrPoolClosed is returned by Add and its variants once the WaitGroupPool
+// has been shut down via Close.
+var ErrPoolClosed = errors.New("waitgrouppool: pool is closed")
+
 // WaitGroupPool has the same role and close to the
 // same API as the Golang sync.WaitGroup but adds a limit of
-// the amount of goroutines started concurrently.
+// the amount of goroutines started concurrently, enforced by
+// composing a Semaphore.
 type WaitGroupPool struct {
     Size int
     timeout time.Duration
-    current chan struct{}
+    sem     *Semaphore
     wg      sync.WaitGroup
+
+    doneMu      sync.Mutex
+    outstanding int
+    done        chan struct{}
+
+    panicHandler func(interface{})
+
+    errOnce sync.Once
+    err     error
+
+    ctx    context.Context
+    cancel context.CancelFunc
 }
 
 type Option func(wgp *WaitGroupPool)
@@ -43,12 +62,16 @@ func New(limit int, opts ...Option) *WaitGroupPool {
     if limit > 0 {
         size = limit
     }
+    ctx, cancel := context.WithCancel(context.Background())
     wgp := &WaitGroupPool{
         Size: size,
 
         timeout: DEFAULT_ASSIGNMENT_TIMEOUT,
-        current: make(chan struct{}, size),
+        sem:     NewSemaphore(size),
         wg:      sync.WaitGroup{},
+        done:    make(chan struct{}),
+        ctx:     ctx,
+        cancel:  cancel,
     }
 
     for _, opt := range opts {
@@ -107,71 +130,232 @@ func (s *WaitGroupPool) AddWithContext(ctx context.Context, delta int) (int, err
     return delta, nil
 }
 
-func (s *WaitGroupPool) AddWithContextCocurrent(ctx context.Context, delta int) (int, error) {
+// AddWithContextConcurrent increments the internal WaitGroup counter by
+// acquiring delta slots concurrently instead of one at a time. If the
+// context is canceled or a slot acquisition fails, the acquisition is
+// aborted, any slots already acquired for this call are released, and the
+// first error encountered is returned.
+func (s *WaitGroupPool) AddWithContextConcurrent(ctx context.Context, delta int) (int, error) {
     if delta < 1 {
         return 0, errors.New("You are trying to add a negative delta, which is not supported. Use Done instead")
     }
 
-    errCh := make(chan error)
-    grCh := make(chan struct{}, delta)
-    complete := make(chan bool)
+    ctx, cancel := context.WithCancel(ctx)
+    defer cancel()
+
+    var acquired int64
+    var errOnce sync.Once
+    var firstErr error
+    var wg sync.WaitGroup
 
+    wg.Add(delta)
     for i := 0; i < delta; i++ {
         go func() {
-            _, err := s.atomicAddWithContext(ctx)
-            if err != nil {
-                errCh <- err
-                close(errCh)
+            defer wg.Done()
+
+            if _, err := s.atomicAddWithContext(ctx); err != nil {
+                errOnce.Do(func() {
+                    firstErr = err
+                    cancel()
+                })
                 return
-            } else {
-                grCh <- struct{}{}
             }
+            atomic.AddInt64(&acquired, 1)
         }()
     }
+    wg.Wait()
 
-    go func() {
-        for {
-            if len(grCh) == delta {
-                complete <- true
-                close(complete)
-                close(grCh)
-                return
-            }
+    if firstErr != nil {
+        for i := int64(0); i < acquired; i++ {
+            s.Done()
         }
-    }()
-
-    select {
-        case err := <- errCh:
-            return len(grCh), err
-        case <- ctx.Done():
-            return len(grCh), ctx.Err()
-        case <- complete:
-            return delta, nil 
+        return 0, firstErr
     }
 
     return delta, nil
 }
 
+// AddWithContextCocurrent is a deprecated alias kept for callers relying on
+// the original (misspelled) name.
+//
+// Deprecated: use AddWithContextConcurrent instead.
+func (s *WaitGroupPool) AddWithContextCocurrent(ctx context.Context, delta int) (int, error) {
+    return s.AddWithContextConcurrent(ctx, delta)
+}
+
 func (s *WaitGroupPool) atomicAddWithContext(ctx context.Context) (int, error) {
-    select {
-    case <-ctx.Done():
-        return 0, ctx.Err()
-    case s.current <- struct{}{}:
-        break
+    if s.ctx.Err() != nil {
+        return 0, ErrPoolClosed
     }
-    s.wg.Add(1)
+    if doneCtx, err := s.sem.LockWithContexts(s.ctx, ctx); err != nil {
+        if doneCtx == s.ctx {
+            return 0, ErrPoolClosed
+        }
+        return 0, err
+    }
+    s.onAcquired()
+
     return 1, nil
 }
 
+// onAcquired records a newly-acquired slot against the WaitGroup counter and,
+// under the same critical section, starts a fresh Add/Wait cycle for
+// DoneChan if none was already in flight. Keeping the outstanding count and
+// the done-channel swap inside a single critical section (rather than an
+// atomic counter checked separately from the lock) is what prevents a
+// Done call from closing a channel that belongs to a cycle that started
+// after it.
+func (s *WaitGroupPool) onAcquired() {
+    s.doneMu.Lock()
+    s.outstanding++
+    if s.outstanding == 1 {
+        s.done = make(chan struct{})
+    }
+    s.doneMu.Unlock()
+
+    s.wg.Add(1)
+}
+
+// tryAcquire acquires a slot without blocking, performing the same
+// outstanding/DoneChan bookkeeping as atomicAddWithContext. It reports
+// whether a slot was acquired.
+func (s *WaitGroupPool) tryAcquire() bool {
+    if !s.sem.TryLock() {
+        return false
+    }
+    s.onAcquired()
+
+    return true
+}
+
 // Done decrements the WaitGroupPool counter.
 // See sync.WaitGroup documentation for more information.
 func (s *WaitGroupPool) Done() {
-    <-s.current
+    s.sem.Unlock()
+    s.signalDone()
+}
+
+// signalDone decrements the WaitGroup counter and, under the same critical
+// section as onAcquired, closes the current DoneChan cycle once the last
+// outstanding slot is accounted for. It is split out from Done so that
+// runGo can release the semaphore slot and finish reporting a panic/error
+// before anything waiting on Wait or DoneChan is allowed to observe
+// completion.
+func (s *WaitGroupPool) signalDone() {
     s.wg.Done()
+
+    s.doneMu.Lock()
+    s.outstanding--
+    if s.outstanding == 0 {
+        close(s.done)
+    }
+    s.doneMu.Unlock()
+}
+
+// DoneChan returns a channel that is closed when the internal WaitGroup
+// counter transitions to zero, so callers can select on it alongside a
+// context or timer instead of blocking on Wait.
+//
+// The returned channel is only valid for the current Add/Wait cycle: once
+// closed, it stays closed even after more work is added. Call DoneChan
+// again after the next Add to get the channel for the new cycle.
+func (s *WaitGroupPool) DoneChan() <-chan struct{} {
+    s.doneMu.Lock()
+    defer s.doneMu.Unlock()
+
+    return s.done
+}
+
+// Close causes all currently-blocked and future Add/AddWithContext calls
+// (and their variants) to return ErrPoolClosed immediately. Goroutines
+// already spawned via Go/GoWithContext, or already running when Close is
+// called, are unaffected and Wait still drains them normally. Close is safe
+// to call more than once.
+func (s *WaitGroupPool) Close() {
+    s.cancel()
 }
 
-// Wait blocks until the WaitGroupPool counter is zero.
+// Wait blocks until the WaitGroupPool counter is zero, then returns the
+// first error recorded by Go or GoWithContext, if any. A panic recovered
+// from a goroutine started by Go or GoWithContext counts as an error here
+// unless a panic handler was registered via SetPanicHandler.
+//
 // See sync.WaitGroup documentation for more information.
-func (s *WaitGroupPool) Wait() {
+func (s *WaitGroupPool) Wait() error {
     s.wg.Wait()
+
+    return s.err
+}
+
+// SetPanicHandler registers a function to be called with the recovered
+// value whenever a goroutine started via Go or GoWithContext panics,
+// instead of the panic being recorded as the error returned by Wait. The
+// handler runs on the panicking goroutine, after its slot has already been
+// released.
+func (s *WaitGroupPool) SetPanicHandler(handler func(interface{})) {
+    s.panicHandler = handler
+}
+
+// Go acquires a slot (using the configured timeout) then calls fn in a new
+// goroutine, guaranteeing the slot is released via Done even if fn panics.
+// If Add fails to acquire a slot, fn is not called.
+func (s *WaitGroupPool) Go(fn func()) {
+    if _, err := s.Add(1); err != nil {
+        return
+    }
+
+    go s.runGo(func() error {
+        fn()
+        return nil
+    })
+}
+
+// GoWithContext acquires a slot, blocking until one is available or ctx is
+// done, then calls fn in a new goroutine, guaranteeing the slot is released
+// via Done even if fn panics. It returns an error immediately if the slot
+// could not be acquired; fn's own error is recorded and surfaced by Wait
+// instead, since fn runs asynchronously.
+func (s *WaitGroupPool) GoWithContext(ctx context.Context, fn func(context.Context) error) error {
+    if _, err := s.AddWithContext(ctx, 1); err != nil {
+        return err
+    }
+
+    go s.runGo(func() error {
+        return fn(ctx)
+    })
+
+    return nil
+}
+
+func (s *WaitGroupPool) runGo(fn func() error) {
+    // signalDone (the WaitGroup counter) must not fire until the recover
+    // below has finished recording the error/panic, otherwise a concurrent
+    // Wait can return before s.err is written. The semaphore slot, on the
+    // other hand, must be released up front so SetPanicHandler's
+    // already-released guarantee holds even while the handler is still
+    // running. So the two halves of Done are split and ordered via defer
+    // (LIFO): signalDone is registered first to run last, the slot release
+    // happens at the top of the recover defer so it runs before recover.
+    defer s.signalDone()
+    defer func() {
+        s.sem.Unlock()
+
+        if r := recover(); r != nil {
+            if s.panicHandler != nil {
+                s.panicHandler(r)
+                return
+            }
+            s.recordErr(fmt.Errorf("waitgrouppool: goroutine panicked: %v", r))
+        }
+    }()
+
+    if err := fn(); err != nil {
+        s.recordErr(err)
+    }
+}
+
+func (s *WaitGroupPool) recordErr(err error) {
+    s.errOnce.Do(func() {
+        s.err = err
+    })
 }
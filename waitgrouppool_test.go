@@ -2,6 +2,8 @@ package waitgrouppool
 
 import (
     "context"
+    "errors"
+    "sync"
     "sync/atomic"
     "testing"
 )
@@ -30,7 +32,7 @@ func TestThrottling(t *testing.T) {
 
     swg := New(4)
 
-    if len(swg.current) != 0 {
+    if swg.sem.Len() != 0 {
         t.Fatalf("the WaitGroupPool should start with zero.")
     }
 
@@ -39,7 +41,7 @@ func TestThrottling(t *testing.T) {
         go func(c *uint32) {
             defer swg.Done()
             atomic.AddUint32(c, 1)
-            if len(swg.current) > 4 {
+            if swg.sem.Len() > 4 {
                 t.Fatalf("not the good amount of routines spawned.")
                 return
             }
@@ -52,7 +54,7 @@ func TestThrottling(t *testing.T) {
 func TestNoThrottling(t *testing.T) {
     var c uint32
     swg := New(0)
-    if len(swg.current) != 0 {
+    if swg.sem.Len() != 0 {
         t.Fatalf("the WaitGroupPool should start with zero.")
     }
     for i := 0; i < 10000; i++ {
@@ -68,6 +70,178 @@ func TestNoThrottling(t *testing.T) {
     }
 }
 
+// TestDoneChanConcurrentReuse stresses the outstanding/done-channel handoff
+// between cycles: many goroutines repeatedly drive the counter 0->1->0 on
+// the same pool, so a cycle's Done racing the next cycle's Add would close
+// a channel belonging to the wrong cycle (or double-close one).
+func TestDoneChanConcurrentReuse(t *testing.T) {
+    swg := New(0)
+    const goroutines = 8
+    const iterations = 2000
+
+    var wg sync.WaitGroup
+    wg.Add(goroutines)
+    for i := 0; i < goroutines; i++ {
+        go func() {
+            defer wg.Done()
+            for j := 0; j < iterations; j++ {
+                swg.Add(1)
+                _ = swg.DoneChan()
+                swg.Done()
+            }
+        }()
+    }
+    wg.Wait()
+}
+
+func TestGo(t *testing.T) {
+    swg := New(4)
+    var c uint32
+
+    for i := 0; i < 1000; i++ {
+        swg.Go(func() {
+            atomic.AddUint32(&c, 1)
+        })
+    }
+
+    if err := swg.Wait(); err != nil {
+        t.Fatalf("Wait returned error: %v", err)
+    }
+
+    if c != 1000 {
+        t.Fatalf("%d, not all routines have been executed.", c)
+    }
+}
+
+func TestGoPanicHandlerSlotAlreadyReleased(t *testing.T) {
+    swg := New(1)
+
+    var acquiredInHandler bool
+    swg.SetPanicHandler(func(interface{}) {
+        acquiredInHandler = swg.sem.TryLock()
+    })
+
+    swg.Go(func() {
+        panic("boom")
+    })
+
+    if err := swg.Wait(); err != nil {
+        t.Fatalf("Wait returned error: %v", err)
+    }
+
+    if !acquiredInHandler {
+        t.Fatalf("panic handler should observe the slot already released.")
+    }
+}
+
+func TestGoPanicHandler(t *testing.T) {
+    swg := New(1)
+
+    var recovered interface{}
+    swg.SetPanicHandler(func(r interface{}) {
+        recovered = r
+    })
+
+    swg.Go(func() {
+        panic("boom")
+    })
+
+    if err := swg.Wait(); err != nil {
+        t.Fatalf("Wait returned error: %v", err)
+    }
+
+    if recovered != "boom" {
+        t.Fatalf("panic handler received %v, want \"boom\"", recovered)
+    }
+}
+
+func TestGoPanicRecordsError(t *testing.T) {
+    swg := New(1)
+
+    swg.Go(func() {
+        panic("boom")
+    })
+
+    if err := swg.Wait(); err == nil {
+        t.Fatalf("Wait should return the recovered panic as an error when no panic handler is set.")
+    }
+}
+
+func TestGoWithContext(t *testing.T) {
+    swg := New(4)
+    var c uint32
+
+    for i := 0; i < 1000; i++ {
+        if err := swg.GoWithContext(context.Background(), func(ctx context.Context) error {
+            atomic.AddUint32(&c, 1)
+            return nil
+        }); err != nil {
+            t.Fatalf("GoWithContext returned error: %v", err)
+        }
+    }
+
+    if err := swg.Wait(); err != nil {
+        t.Fatalf("Wait returned error: %v", err)
+    }
+
+    if c != 1000 {
+        t.Fatalf("%d, not all routines have been executed.", c)
+    }
+}
+
+func TestGoWithContextCanceledBeforeAcquire(t *testing.T) {
+    swg := New(1)
+
+    if _, err := swg.Add(1); err != nil {
+        t.Fatalf("Add returned error: %v", err)
+    }
+
+    ctx, cancel := context.WithCancel(context.Background())
+    cancel()
+
+    if err := swg.GoWithContext(ctx, func(context.Context) error {
+        t.Fatalf("fn should not run when the slot could not be acquired.")
+        return nil
+    }); err != context.Canceled {
+        t.Fatalf("GoWithContext returned %v, want context.Canceled", err)
+    }
+
+    swg.Done()
+    swg.Wait()
+}
+
+func TestGoWithContextErrorPropagation(t *testing.T) {
+    swg := New(1)
+    boom := errors.New("boom")
+
+    if err := swg.GoWithContext(context.Background(), func(context.Context) error {
+        return boom
+    }); err != nil {
+        t.Fatalf("GoWithContext returned error: %v", err)
+    }
+
+    if err := swg.Wait(); err != boom {
+        t.Fatalf("Wait returned %v, want %v", err, boom)
+    }
+}
+
+func TestClose(t *testing.T) {
+    swg := New(1)
+
+    if _, err := swg.Add(1); err != nil {
+        t.Fatalf("Add returned error: %v", err)
+    }
+
+    swg.Close()
+
+    if _, err := swg.Add(1); err != ErrPoolClosed {
+        t.Fatalf("Add after Close returned %v, want ErrPoolClosed", err)
+    }
+
+    swg.Done()
+    swg.Wait()
+}
+
 func TestAddWithContext(t *testing.T) {
     ctx, cancelFunc := context.WithCancel(context.TODO())
 